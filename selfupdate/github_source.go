@@ -0,0 +1,226 @@
+package selfupdate
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrPatchUnsupported is returned by Source.FetchPatch implementations that
+// can't produce binary patches, telling Updater to fall back to FetchBinary.
+var ErrPatchUnsupported = errors.New("selfupdate: source does not support binary patches")
+
+// GitHubReleasesSource implements Source against GitHub Releases: it looks
+// up a release for Owner/Repo, picks the asset named "<Repo>-GOOS-GOARCH",
+// and reads its SHA256 from a companion "checksums.txt" asset in the same
+// release. It never produces patches; FetchPatch always returns
+// ErrPatchUnsupported so Updater falls back to a full binary download.
+type GitHubReleasesSource struct {
+	Owner string
+	Repo  string
+	Token string // optional; sent as "Authorization: token <Token>", e.g. for private repos or to avoid rate limiting
+
+	Client *http.Client // optional override, defaults to http.DefaultClient
+	// BaseURL overrides the GitHub API origin, defaulting to
+	// https://api.github.com. Tests point this at an httptest.Server.
+	BaseURL string
+}
+
+func (s *GitHubReleasesSource) baseURL() string {
+	if s.BaseURL != "" {
+		return s.BaseURL
+	}
+	return "https://api.github.com"
+}
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		ID   int64  `json:"id"`
+		Size int64  `json:"size"`
+	} `json:"assets"`
+}
+
+func (s *GitHubReleasesSource) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return http.DefaultClient
+}
+
+func (s *GitHubReleasesSource) assetName() string {
+	return s.Repo + "-" + plat
+}
+
+func (s *GitHubReleasesSource) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if s.Token != "" {
+		req.Header.Set("Authorization", "token "+s.Token)
+	}
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("selfupdate: github %s: unexpected status %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// fetchAsset downloads a release asset by id through the GitHub API. This is
+// the only way that works for both public and private repos: a private
+// asset's browser_download_url 404s (or redirects to a login page) when
+// fetched directly, even with a valid token.
+func (s *GitHubReleasesSource) fetchAsset(ctx context.Context, id int64) (*http.Response, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/assets/%d", s.baseURL(), s.Owner, s.Repo, id)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/octet-stream")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "token "+s.Token)
+	}
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("selfupdate: github %s: unexpected status %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func (s *GitHubReleasesSource) release(ctx context.Context, channel string) (githubRelease, error) {
+	base := fmt.Sprintf("%s/repos/%s/%s/releases", s.baseURL(), s.Owner, s.Repo)
+
+	if channel == "" || channel == "stable" {
+		resp, err := s.get(ctx, base+"/latest")
+		if err != nil {
+			return githubRelease{}, err
+		}
+		defer resp.Body.Close()
+		var rel githubRelease
+		err = json.NewDecoder(resp.Body).Decode(&rel)
+		return rel, err
+	}
+
+	resp, err := s.get(ctx, base)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	defer resp.Body.Close()
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return githubRelease{}, err
+	}
+	for _, r := range releases {
+		if strings.Contains(strings.ToLower(r.TagName), channel) {
+			return r, nil
+		}
+	}
+	return githubRelease{}, fmt.Errorf("selfupdate: no %s/%s release found for channel %q", s.Owner, s.Repo, channel)
+}
+
+func (s *GitHubReleasesSource) releaseByTag(ctx context.Context, tag string) (githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", s.baseURL(), s.Owner, s.Repo, tag)
+	resp, err := s.get(ctx, url)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	defer resp.Body.Close()
+	var rel githubRelease
+	err = json.NewDecoder(resp.Body).Decode(&rel)
+	return rel, err
+}
+
+func (s *GitHubReleasesSource) releaseForVersion(ctx context.Context, version string) (githubRelease, error) {
+	if rel, err := s.releaseByTag(ctx, version); err == nil {
+		return rel, nil
+	}
+	return s.releaseByTag(ctx, "v"+version)
+}
+
+func findAsset(rel githubRelease, name string) (id int64, size int64, err error) {
+	for _, a := range rel.Assets {
+		if a.Name == name {
+			return a.ID, a.Size, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("selfupdate: release %s has no asset named %q", rel.TagName, name)
+}
+
+func (s *GitHubReleasesSource) fetchSha256(ctx context.Context, rel githubRelease, assetName string) ([]byte, error) {
+	id, _, err := findAsset(rel, "checksums.txt")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.fetchAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == assetName {
+			return hex.DecodeString(fields[0])
+		}
+	}
+	return nil, fmt.Errorf("selfupdate: release %s checksums.txt has no checksum for %q", rel.TagName, assetName)
+}
+
+func (s *GitHubReleasesSource) LatestVersion(ctx context.Context, channel string) (Info, error) {
+	var info Info
+	rel, err := s.release(ctx, channel)
+	if err != nil {
+		return info, err
+	}
+	sha, err := s.fetchSha256(ctx, rel, s.assetName())
+	if err != nil {
+		return info, err
+	}
+	_, size, err := findAsset(rel, s.assetName())
+	if err != nil {
+		return info, err
+	}
+	info.Version = strings.TrimPrefix(rel.TagName, "v")
+	info.Sha256 = sha
+	info.Size = size
+	return info, nil
+}
+
+func (s *GitHubReleasesSource) FetchBinary(ctx context.Context, version string) (io.ReadCloser, error) {
+	rel, err := s.releaseForVersion(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	id, _, err := findAsset(rel, s.assetName())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.fetchAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *GitHubReleasesSource) FetchPatch(ctx context.Context, from, to string) (io.ReadCloser, error) {
+	return nil, ErrPatchUnsupported
+}
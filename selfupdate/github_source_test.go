@@ -0,0 +1,113 @@
+package selfupdate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func githubTestServer(t *testing.T, assetBody, checksumsBody []byte) *httptest.Server {
+	t.Helper()
+	assetName := "myrepo-" + plat
+	release := map[string]interface{}{
+		"tag_name": "v1.2.3",
+		"assets": []map[string]interface{}{
+			{"name": assetName, "id": 1, "size": int64(len(assetBody))},
+			{"name": "checksums.txt", "id": 2, "size": int64(len(checksumsBody))},
+		},
+	}
+	relBytes, err := json.Marshal(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	serveRelease := func(w http.ResponseWriter, r *http.Request) { w.Write(relBytes) }
+	mux.HandleFunc("/repos/acme/myrepo/releases/latest", serveRelease)
+	mux.HandleFunc("/repos/acme/myrepo/releases/tags/1.2.3", serveRelease)
+	mux.HandleFunc("/repos/acme/myrepo/releases/tags/v1.2.3", serveRelease)
+	mux.HandleFunc("/repos/acme/myrepo/releases/assets/1", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "application/octet-stream" {
+			t.Errorf("asset request Accept header = %q, want application/octet-stream", got)
+		}
+		w.Write(assetBody)
+	})
+	mux.HandleFunc("/repos/acme/myrepo/releases/assets/2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(checksumsBody)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestGitHubReleasesSourceLatestVersion(t *testing.T) {
+	binary := []byte("binary-contents")
+	sum := sha256.Sum256(binary)
+	assetName := "myrepo-" + plat
+	checksums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), assetName))
+
+	srv := githubTestServer(t, binary, checksums)
+	defer srv.Close()
+
+	s := &GitHubReleasesSource{Owner: "acme", Repo: "myrepo", BaseURL: srv.URL}
+	info, err := s.LatestVersion(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "1.2.3" {
+		t.Fatalf("Version = %q, want 1.2.3", info.Version)
+	}
+	if !bytes.Equal(info.Sha256, sum[:]) {
+		t.Fatalf("Sha256 = %x, want %x", info.Sha256, sum)
+	}
+	if info.Size != int64(len(binary)) {
+		t.Fatalf("Size = %d, want %d", info.Size, len(binary))
+	}
+}
+
+func TestGitHubReleasesSourceFetchBinary(t *testing.T) {
+	binary := []byte("binary-contents")
+	checksums := []byte("deadbeef  somethingelse\n")
+	srv := githubTestServer(t, binary, checksums)
+	defer srv.Close()
+
+	s := &GitHubReleasesSource{Owner: "acme", Repo: "myrepo", BaseURL: srv.URL}
+	rc, err := s.FetchBinary(context.Background(), "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, binary) {
+		t.Fatalf("FetchBinary content = %q, want %q", got, binary)
+	}
+}
+
+func TestGitHubReleasesSourceMissingChecksum(t *testing.T) {
+	binary := []byte("binary-contents")
+	checksums := []byte("deadbeef  somethingelse\n")
+	srv := githubTestServer(t, binary, checksums)
+	defer srv.Close()
+
+	s := &GitHubReleasesSource{Owner: "acme", Repo: "myrepo", BaseURL: srv.URL}
+	if _, err := s.LatestVersion(context.Background(), ""); err == nil {
+		t.Fatal("LatestVersion() = nil error, want error for missing checksum")
+	}
+}
+
+func TestGitHubReleasesSourceFetchPatchUnsupported(t *testing.T) {
+	s := &GitHubReleasesSource{Owner: "acme", Repo: "myrepo"}
+	if _, err := s.FetchPatch(context.Background(), "1.0.0", "1.1.0"); err != ErrPatchUnsupported {
+		t.Fatalf("FetchPatch() err = %v, want ErrPatchUnsupported", err)
+	}
+}
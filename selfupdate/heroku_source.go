@@ -0,0 +1,82 @@
+package selfupdate
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// HerokuSource implements Source against the original go-selfupdate
+// protocol: a JSON manifest plus bsdiff patches and gzipped binaries served
+// from flat, Heroku-style S3 URLs. It's the default Source, built from
+// Updater's ApiURL/BinURL/DiffURL/Requester when Updater.Source isn't set.
+type HerokuSource struct {
+	ApiURL    string
+	BinURL    string
+	DiffURL   string
+	CmdName   string
+	Requester Requester // Optional parameter to override existing http request handler
+}
+
+// LatestVersion ignores channel: the Heroku-style protocol has no concept
+// of release channels.
+func (s *HerokuSource) LatestVersion(ctx context.Context, channel string) (Info, error) {
+	var info Info
+	r, err := s.fetch(s.ApiURL + s.CmdName + "/" + plat + ".json")
+	if err != nil {
+		return info, err
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(&info); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+func (s *HerokuSource) FetchBinary(ctx context.Context, version string) (io.ReadCloser, error) {
+	r, err := s.fetch(s.BinURL + s.CmdName + "/" + version + "/" + plat + ".gz")
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{gz, r}, nil
+}
+
+func (s *HerokuSource) FetchPatch(ctx context.Context, from, to string) (io.ReadCloser, error) {
+	return s.fetch(s.DiffURL + s.CmdName + "/" + from + "/" + to + "/" + plat)
+}
+
+func (s *HerokuSource) fetch(url string) (io.ReadCloser, error) {
+	if s.Requester == nil {
+		s.Requester = &HTTPRequester{}
+	}
+
+	readCloser, err := s.Requester.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if readCloser == nil {
+		return nil, fmt.Errorf("Fetch was expected to return non-nil ReadCloser")
+	}
+
+	return readCloser, nil
+}
+
+// gzipReadCloser closes both the gzip.Reader and the underlying stream it
+// wraps.
+type gzipReadCloser struct {
+	*gzip.Reader
+	underlying io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.underlying.Close()
+}
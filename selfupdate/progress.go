@@ -0,0 +1,27 @@
+package selfupdate
+
+import "io"
+
+// progressReader reports bytes read so far to onProgress as the wrapped
+// stream is consumed by the patcher.
+type progressReader struct {
+	io.ReadCloser
+	done, total int64
+	onProgress  func(bytesDone, bytesTotal int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	p.done += int64(n)
+	p.onProgress(p.done, p.total)
+	return n, err
+}
+
+// withProgress wraps rc so each Read reports progress through OnProgress, if
+// set. total may be 0 if the size isn't known in advance.
+func (u *Updater) withProgress(rc io.ReadCloser, total int64) io.ReadCloser {
+	if u.OnProgress == nil {
+		return rc
+	}
+	return &progressReader{ReadCloser: rc, total: total, onProgress: u.OnProgress}
+}
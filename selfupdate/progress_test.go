@@ -0,0 +1,67 @@
+package selfupdate
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+type nopCloser struct {
+	io.Reader
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestProgressReaderAccumulatesAcrossReads(t *testing.T) {
+	data := []byte("hello, world")
+	rc := nopCloser{bytes.NewReader(data)}
+
+	var calls [][2]int64
+	pr := &progressReader{
+		ReadCloser: rc,
+		total:      int64(len(data)),
+		onProgress: func(done, total int64) { calls = append(calls, [2]int64{done, total}) },
+	}
+
+	buf := make([]byte, 4)
+	for {
+		_, err := pr.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(calls) == 0 {
+		t.Fatal("onProgress was never called")
+	}
+	for _, c := range calls {
+		if c[1] != int64(len(data)) {
+			t.Fatalf("onProgress total = %d, want %d", c[1], len(data))
+		}
+	}
+	if last := calls[len(calls)-1]; last[0] != int64(len(data)) {
+		t.Fatalf("onProgress final done = %d, want %d", last[0], len(data))
+	}
+}
+
+func TestWithProgressNoOnProgressIsNoOp(t *testing.T) {
+	rc := nopCloser{bytes.NewReader([]byte("data"))}
+	u := &Updater{}
+
+	got := u.withProgress(rc, 100)
+	if _, ok := got.(*progressReader); ok {
+		t.Fatal("withProgress wrapped the reader even though OnProgress is nil")
+	}
+
+	b, err := ioutil.ReadAll(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "data" {
+		t.Fatalf("read %q, want %q", b, "data")
+	}
+}
@@ -0,0 +1,84 @@
+package selfupdate
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenerFDsEnv is the environment variable execRestarter publishes the
+// inherited listener file descriptors on; ListenersFromEnv reads it back in
+// the new process image.
+const listenerFDsEnv = "GOSELFUPDATE_LISTENER_FDS"
+
+// Restarter replaces the running process with the newly-updated binary.
+// The default implementation re-execs the process image in place on
+// platforms that support it; Updater.Restarter can be set to something
+// else for process models where that isn't appropriate (e.g. a process
+// manager that expects the child to simply exit).
+//
+// Restart only returns if the restart could not be started; on success the
+// calling process is replaced or exits, so Update() never returns.
+type Restarter interface {
+	Restart(listeners []*net.TCPListener) error
+}
+
+func (u *Updater) restarter() Restarter {
+	if u.Restarter != nil {
+		return u.Restarter
+	}
+	return execRestarter{}
+}
+
+// maybeRestart re-execs the process after a successful update when
+// RestartAfterUpdate is set. If ListenerHandoff is set, the returned
+// listeners are passed through to the new process image so in-flight
+// connections on them aren't dropped.
+func (u *Updater) maybeRestart() error {
+	if !u.RestartAfterUpdate {
+		return nil
+	}
+	var listeners []*net.TCPListener
+	if u.ListenerHandoff != nil {
+		l, err := u.ListenerHandoff()
+		if err != nil {
+			return err
+		}
+		listeners = l
+	}
+	return u.restarter().Restart(listeners)
+}
+
+// ListenersFromEnv reconstructs the net.TCPListeners published by
+// execRestarter via GOSELFUPDATE_LISTENER_FDS, letting a process started
+// with RestartAfterUpdate/ListenerHandoff resume serving on the same
+// sockets its parent was listening on. It returns a nil slice if the env
+// var isn't set, e.g. on first run or when ListenerHandoff wasn't used.
+func ListenersFromEnv() ([]*net.TCPListener, error) {
+	raw := os.Getenv(listenerFDsEnv)
+	if raw == "" {
+		return nil, nil
+	}
+
+	fields := strings.Split(raw, ",")
+	listeners := make([]*net.TCPListener, 0, len(fields))
+	for _, field := range fields {
+		fd, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("selfupdate: invalid fd %q in %s: %v", field, listenerFDsEnv, err)
+		}
+
+		l, err := net.FileListener(os.NewFile(uintptr(fd), "listener"))
+		if err != nil {
+			return nil, err
+		}
+		tcpListener, ok := l.(*net.TCPListener)
+		if !ok {
+			return nil, fmt.Errorf("selfupdate: fd %d is not a TCP listener", fd)
+		}
+		listeners = append(listeners, tcpListener)
+	}
+	return listeners, nil
+}
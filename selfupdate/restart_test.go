@@ -0,0 +1,123 @@
+package selfupdate
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// openTestListeners opens n real TCP listeners and sets listenerFDsEnv to
+// their fds, the same way execRestarter does before an exec. It registers a
+// cleanup that restores the env var and closes the listeners' files.
+func openTestListeners(t *testing.T, n int) []*net.TCPListener {
+	t.Helper()
+	listeners := make([]*net.TCPListener, n)
+	fds := make([]string, n)
+	for i := range listeners {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tcpListener := l.(*net.TCPListener)
+		listeners[i] = tcpListener
+
+		f, err := tcpListener.File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds[i] = strconv.FormatUint(uint64(f.Fd()), 10)
+		t.Cleanup(func() { f.Close() })
+	}
+
+	orig, hadOrig := os.LookupEnv(listenerFDsEnv)
+	os.Setenv(listenerFDsEnv, strings.Join(fds, ","))
+	t.Cleanup(func() {
+		if hadOrig {
+			os.Setenv(listenerFDsEnv, orig)
+		} else {
+			os.Unsetenv(listenerFDsEnv)
+		}
+		for _, l := range listeners {
+			l.Close()
+		}
+	})
+
+	return listeners
+}
+
+func TestListenersFromEnvUnset(t *testing.T) {
+	os.Unsetenv(listenerFDsEnv)
+
+	got, err := ListenersFromEnv()
+	if err != nil {
+		t.Fatalf("ListenersFromEnv() err = %v, want nil", err)
+	}
+	if got != nil {
+		t.Fatalf("ListenersFromEnv() = %v, want nil", got)
+	}
+}
+
+func TestListenersFromEnvReconstructsListeners(t *testing.T) {
+	want := openTestListeners(t, 2)
+
+	got, err := ListenersFromEnv()
+	if err != nil {
+		t.Fatalf("ListenersFromEnv() err = %v, want nil", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len(ListenersFromEnv()) = %d, want %d", len(got), len(want))
+	}
+
+	for i, l := range got {
+		defer l.Close()
+		if l.Addr().String() != want[i].Addr().String() {
+			t.Errorf("listener %d Addr() = %s, want %s", i, l.Addr(), want[i].Addr())
+		}
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("dial reconstructed listener %d: %v", i, err)
+		}
+		conn.Close()
+	}
+}
+
+func TestListenersFromEnvInvalidFd(t *testing.T) {
+	orig, hadOrig := os.LookupEnv(listenerFDsEnv)
+	os.Setenv(listenerFDsEnv, "not-a-number")
+	t.Cleanup(func() {
+		if hadOrig {
+			os.Setenv(listenerFDsEnv, orig)
+		} else {
+			os.Unsetenv(listenerFDsEnv)
+		}
+	})
+
+	if _, err := ListenersFromEnv(); err == nil {
+		t.Fatal("ListenersFromEnv() with malformed fd list = nil error, want error")
+	}
+}
+
+func TestListenersFromEnvNotASocket(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "not-a-socket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	orig, hadOrig := os.LookupEnv(listenerFDsEnv)
+	os.Setenv(listenerFDsEnv, strconv.FormatUint(uint64(f.Fd()), 10))
+	t.Cleanup(func() {
+		if hadOrig {
+			os.Setenv(listenerFDsEnv, orig)
+		} else {
+			os.Unsetenv(listenerFDsEnv)
+		}
+	})
+
+	if _, err := ListenersFromEnv(); err == nil {
+		t.Fatal("ListenersFromEnv() with a non-socket fd = nil error, want error")
+	}
+}
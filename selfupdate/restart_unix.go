@@ -0,0 +1,47 @@
+//go:build !windows
+
+package selfupdate
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/kardianos/osext"
+)
+
+// execRestarter re-execs the current process image via syscall.Exec,
+// inheriting argv and env. Listeners being handed off survive the exec as
+// open file descriptors (their close-on-exec flag is cleared); their numbers are
+// published via listenerFDsEnv so the new process image can pick them back
+// up with ListenersFromEnv.
+type execRestarter struct{}
+
+func (execRestarter) Restart(listeners []*net.TCPListener) error {
+	argv0, err := osext.Executable()
+	if err != nil {
+		return err
+	}
+
+	env := os.Environ()
+	if len(listeners) > 0 {
+		fds := make([]string, len(listeners))
+		for i, l := range listeners {
+			f, err := l.File()
+			if err != nil {
+				return err
+			}
+			// File() returns a close-on-exec duplicate; clear that flag so
+			// the descriptor stays open across syscall.Exec.
+			if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), syscall.F_SETFD, 0); errno != 0 {
+				return errno
+			}
+			fds[i] = strconv.FormatUint(uint64(f.Fd()), 10)
+		}
+		env = append(env, listenerFDsEnv+"="+strings.Join(fds, ","))
+	}
+
+	return syscall.Exec(argv0, os.Args, env)
+}
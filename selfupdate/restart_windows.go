@@ -0,0 +1,39 @@
+package selfupdate
+
+import (
+	"net"
+	"os"
+	"os/exec"
+
+	"github.com/kardianos/osext"
+)
+
+// execRestarter has no way to replace the running process image on
+// Windows, so it spawns a child with the same argv and env instead and
+// exits cleanly once the child has started. Listener handoff isn't
+// supported here: the listeners are closed so the OS can rebind them
+// immediately in the child.
+type execRestarter struct{}
+
+func (execRestarter) Restart(listeners []*net.TCPListener) error {
+	argv0, err := osext.Executable()
+	if err != nil {
+		return err
+	}
+
+	for _, l := range listeners {
+		l.Close()
+	}
+
+	cmd := exec.Command(argv0, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,152 @@
+package selfupdate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const historyPath = "history.json"
+
+// ErrNoPreviousVersion is returned by Rollback when there is no backed-up
+// executable to restore.
+var ErrNoPreviousVersion = errors.New("selfupdate: no previous version to roll back to")
+
+// versionHistoryEntry records one previously-installed version so Rollback
+// can restore it later.
+type versionHistoryEntry struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+}
+
+func (u *Updater) historyFile() string {
+	return u.getExecRelativeDir(u.Dir + historyPath)
+}
+
+func (u *Updater) readHistory() ([]versionHistoryEntry, error) {
+	b, err := ioutil.ReadFile(u.historyFile())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var history []versionHistoryEntry
+	if err := json.Unmarshal(b, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (u *Updater) writeHistory(history []versionHistoryEntry) error {
+	b, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(u.historyFile(), b, 0644)
+}
+
+// backupCurrentBinary copies the running executable to a versioned filename
+// under Dir before an update is applied, so it can be restored later.
+func (u *Updater) backupCurrentBinary() (string, error) {
+	exe, err := executablePath()
+	if err != nil {
+		return "", err
+	}
+	dest := u.getExecRelativeDir(u.Dir + u.CmdName + "-" + u.CurrentVersion)
+	if err := copyFile(dest, exe); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// recordHistory appends backupPath to the persisted version history and, if
+// KeepPreviousVersions is set, prunes the oldest backups beyond that limit.
+func (u *Updater) recordHistory(backupPath string) error {
+	history, err := u.readHistory()
+	if err != nil {
+		return err
+	}
+	history = append(history, versionHistoryEntry{Version: u.CurrentVersion, Path: backupPath})
+
+	if u.KeepPreviousVersions > 0 {
+		for len(history) > u.KeepPreviousVersions {
+			os.Remove(history[0].Path)
+			history = history[1:]
+		}
+	}
+
+	return u.writeHistory(history)
+}
+
+// Rollback restores the most recently backed-up executable, undoing the
+// last successful Update(). It is also invoked automatically when
+// VerifyAfterUpdate rejects an update.
+func (u *Updater) Rollback() error {
+	history, err := u.readHistory()
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return ErrNoPreviousVersion
+	}
+	prev := history[len(history)-1]
+
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+	if err := copyFile(exe, prev.Path); err != nil {
+		return err
+	}
+
+	return u.writeHistory(history[:len(history)-1])
+}
+
+func copyFile(dest, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0777); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// commitUpdate runs after the new binary has been written to disk: it
+// records the backup of the previous binary (backupPath) in the version
+// history, runs VerifyAfterUpdate (rolling back
+// automatically if it fails), and finally restarts the process if
+// RestartAfterUpdate is set.
+func (u *Updater) commitUpdate(backupPath string) error {
+	if err := u.recordHistory(backupPath); err != nil {
+		return err
+	}
+
+	if u.VerifyAfterUpdate != nil {
+		if err := u.VerifyAfterUpdate(); err != nil {
+			if rerr := u.Rollback(); rerr != nil {
+				return fmt.Errorf("update verification failed (%q) and rollback failed (%q)", err, rerr)
+			}
+			return fmt.Errorf("update verification failed, rolled back to previous version: %q", err)
+		}
+	}
+
+	return u.maybeRestart()
+}
@@ -0,0 +1,126 @@
+package selfupdate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withTestExecutable points executablePath at a throwaway file under a temp
+// dir for the duration of the test, so backupCurrentBinary/Rollback never
+// touch the real test binary.
+func withTestExecutable(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "testapp")
+	if err := ioutil.WriteFile(exe, []byte("original"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := executablePath
+	executablePath = func() (string, error) { return exe, nil }
+	t.Cleanup(func() { executablePath = orig })
+
+	return exe
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.bin")
+	if err := ioutil.WriteFile(src, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(dir, "nested", "dest.bin")
+
+	if err := copyFile(dest, src); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello" {
+		t.Fatalf("copyFile content = %q, want %q", got, "hello")
+	}
+}
+
+func TestRecordHistoryPrunesOldest(t *testing.T) {
+	withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/", KeepPreviousVersions: 2}
+
+	backups := []string{}
+	for _, v := range []string{"1.0.0", "1.1.0", "1.2.0"} {
+		u.CurrentVersion = v
+		backupPath, err := u.backupCurrentBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		backups = append(backups, backupPath)
+		if err := u.recordHistory(backupPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := u.readHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("len(history) = %d, want 2", len(history))
+	}
+	if history[0].Version != "1.1.0" || history[1].Version != "1.2.0" {
+		t.Fatalf("history versions = %+v, want [1.1.0 1.2.0]", history)
+	}
+
+	if _, err := os.Stat(backups[0]); !os.IsNotExist(err) {
+		t.Fatalf("oldest backup %s should have been pruned, stat err = %v", backups[0], err)
+	}
+}
+
+func TestRollbackRestoresPreviousBinary(t *testing.T) {
+	exe := withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/", CurrentVersion: "1.0.0"}
+
+	backupPath, err := u.backupCurrentBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := u.recordHistory(backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(exe, []byte("new version"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Rollback(); err != nil {
+		t.Fatalf("Rollback() = %v, want nil", err)
+	}
+
+	got, err := ioutil.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "original" {
+		t.Fatalf("executable content after Rollback = %q, want %q", got, "original")
+	}
+
+	history, err := u.readHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("len(history) after Rollback = %d, want 0", len(history))
+	}
+}
+
+func TestRollbackNoPreviousVersion(t *testing.T) {
+	withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/"}
+
+	if err := u.Rollback(); err != ErrNoPreviousVersion {
+		t.Fatalf("Rollback() = %v, want ErrNoPreviousVersion", err)
+	}
+}
@@ -0,0 +1,83 @@
+package selfupdate
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const installIDPath = "install-id"
+
+// installID returns u.InstallID, generating and persisting one under Dir
+// the first time it's needed if it's unset.
+func (u *Updater) installID() (string, error) {
+	if u.InstallID != "" {
+		return u.InstallID, nil
+	}
+
+	path := u.getExecRelativeDir(u.Dir + installIDPath)
+	if b, err := ioutil.ReadFile(path); err == nil {
+		u.InstallID = strings.TrimSpace(string(b))
+		return u.InstallID, nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	u.InstallID = hex.EncodeToString(id)
+
+	os.MkdirAll(u.getExecRelativeDir(u.Dir), 0777)
+	if err := ioutil.WriteFile(path, []byte(u.InstallID), 0644); err != nil {
+		return "", err
+	}
+	return u.InstallID, nil
+}
+
+// inRolloutCohort deterministically decides whether this installation is in
+// the cohort for version, by hashing InstallID+version and comparing the
+// result against Info.Rollout. A Rollout of 0 means unstaged: everyone is
+// included.
+func (u *Updater) inRolloutCohort(version string) (bool, error) {
+	if u.Info.Rollout <= 0 {
+		return true, nil
+	}
+
+	id, err := u.installID()
+	if err != nil {
+		return false, err
+	}
+
+	h := fnv.New64a()
+	io.WriteString(h, id+version)
+	frac := float64(h.Sum64()) / float64(math.MaxUint64)
+	return frac < u.Info.Rollout, nil
+}
+
+// semverLess reports whether a is an earlier version than b, comparing
+// dot-separated numeric components left to right. It's intentionally
+// simple: good enough for the plain major.minor.patch versions this
+// package expects, not a full semver implementation.
+func semverLess(a, b string) bool {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		if an != bn {
+			return an < bn
+		}
+	}
+	return false
+}
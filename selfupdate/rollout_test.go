@@ -0,0 +1,92 @@
+package selfupdate
+
+import "testing"
+
+func TestSemverLess(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.0.0", "1.0.1", true},
+		{"1.0.1", "1.0.0", false},
+		{"1.0.0", "1.0.0", false},
+		{"1.2.0", "1.10.0", true},
+		{"v1.0.0", "1.0.1", true},
+		{"2.0", "1.9.9", false},
+		{"1.0", "1.0.1", true},
+	}
+	for _, c := range cases {
+		if got := semverLess(c.a, c.b); got != c.want {
+			t.Errorf("semverLess(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestInRolloutCohortUnstaged(t *testing.T) {
+	withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/"}
+
+	in, err := u.inRolloutCohort("1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !in {
+		t.Fatal("inRolloutCohort() with Rollout unset = false, want true")
+	}
+}
+
+func TestInRolloutCohortIsStableForSameInstall(t *testing.T) {
+	withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/"}
+	u.Info.Rollout = 0.5
+
+	first, err := u.inRolloutCohort("1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		got, err := u.inRolloutCohort("1.2.3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != first {
+			t.Fatalf("inRolloutCohort() not stable across calls: got %v, want %v", got, first)
+		}
+	}
+}
+
+func TestInRolloutCohortFullRollout(t *testing.T) {
+	withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/"}
+	u.Info.Rollout = 1.0
+
+	in, err := u.inRolloutCohort("1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !in {
+		t.Fatal("inRolloutCohort() with Rollout=1.0 = false, want true")
+	}
+}
+
+func TestInstallIDPersistsAcrossCalls(t *testing.T) {
+	withTestExecutable(t)
+	u := &Updater{CmdName: "testapp", Dir: "update/"}
+
+	first, err := u.installID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == "" {
+		t.Fatal("installID() returned empty string")
+	}
+
+	u2 := &Updater{CmdName: "testapp", Dir: "update/"}
+	second, err := u2.installID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Fatalf("installID() = %q on second Updater, want persisted %q", second, first)
+	}
+}
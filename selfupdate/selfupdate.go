@@ -8,6 +8,10 @@
 //   {
 //       "Version": "2",
 //       "Sha256": "..." // base64
+//       "Signature": "..." // base64, optional, required when Updater.PublicKey is set
+//       "Size": 1234567, // optional, download size in bytes, for Updater.ConfirmUpdate
+//       "Rollout": 0.05, // optional, fraction of installs in [0,1] that should take this update
+//       "MinVersion": "1" // optional, installs older than this are skipped
 //   }
 //
 // then
@@ -28,11 +32,12 @@
 package selfupdate
 
 import (
+	"context"
+	"crypto"
 	"crypto/sha256"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -82,15 +87,68 @@ type Updater struct {
 	DiffURL        string    // Base URL for diff downloads.
 	Dir            string    // Directory to store selfupdate state.
 	Requester      Requester //Optional parameter to override existing http request handler
-	Info           struct {
-		Version string
-		Sha256  []byte
-	}
-	Logger logInterface
+	// Source, if set, overrides ApiURL/BinURL/DiffURL/Requester entirely as
+	// where manifests and binaries are fetched from. Defaults to a
+	// HerokuSource built from those fields, preserving the original
+	// flat-S3-URL protocol; set this to a GitHubReleasesSource (or a custom
+	// Source) to track releases elsewhere.
+	Source Source
+	// Channel is forwarded to Source.LatestVersion, e.g. "stable", "beta",
+	// "nightly". Sources that don't support channels ignore it.
+	Channel string
+	// PublicKey, if set, requires Info.Signature to verify against the
+	// manifest's Version and Sha256 before an update is applied. Supported
+	// key types are *ecdsa.PublicKey and ed25519.PublicKey.
+	PublicKey crypto.PublicKey
+	Info      Info
+	Logger    logInterface
+
+	// RestartAfterUpdate, if true, replaces the running process with the
+	// newly-updated binary once Update() succeeds instead of leaving it on
+	// disk for the user to restart manually.
+	RestartAfterUpdate bool
+	// Restarter overrides how the process image is replaced. Defaults to
+	// re-exec'ing in place via syscall.Exec on platforms that support it.
+	Restarter Restarter
+	// ListenerHandoff, if set, is called just before restarting and should
+	// return any net.Listeners the new process must keep serving on so
+	// in-flight connections aren't dropped.
+	ListenerHandoff func() ([]*net.TCPListener, error)
+
+	// KeepPreviousVersions is how many prior executables to retain in Dir
+	// for Rollback. Older backups beyond this count are deleted as new
+	// updates are applied. Zero keeps everything.
+	KeepPreviousVersions int
+	// VerifyAfterUpdate, if set, runs after an update is written to disk
+	// but before it's trusted (e.g. exec the new binary with --version
+	// under a timeout). A non-nil error triggers an automatic Rollback.
+	VerifyAfterUpdate func() error
+
+	// ConfirmUpdate, if set, is called once UpdateAvailable() reports true
+	// and must return true before any bytes are downloaded or applied.
+	// size is u.Info.Size and may be 0 if the Source didn't report one.
+	// BackgroundRun leaves this nil, i.e. always proceeds; interactive
+	// tools can use it to prompt the user.
+	ConfirmUpdate func(current, next string, size int64) bool
+	// OnProgress, if set, is called as patch/binary bytes are downloaded
+	// and applied, to drive a progress bar.
+	OnProgress func(bytesDone, bytesTotal int64)
+
+	// InstallID is a stable per-installation identifier used together with
+	// Info.Rollout to decide staged-rollout cohort membership. Left empty,
+	// it's generated once and persisted under Dir; set it explicitly to
+	// override that (e.g. in tests).
+	InstallID string
 }
 
+// executablePath resolves the currently-running executable. It's a package
+// variable rather than a direct osext.Executable() call so tests can
+// override it instead of having backupCurrentBinary/Rollback operate on the
+// real test binary.
+var executablePath = osext.Executable
+
 func (u *Updater) getExecRelativeDir(dir string) string {
-	filename, _ := osext.Executable()
+	filename, _ := executablePath()
 	path := filepath.Join(filepath.Dir(filename), dir)
 	return path
 }
@@ -123,23 +181,40 @@ func (u *Updater) Update() error {
 		return err
 	}
 
-	up := update.New().ApplyPatch(update.PATCHTYPE_BSDIFF).VerifyChecksum(u.Info.Sha256)
-
-	// Construct the Patch URL
-	patchURL := u.DiffURL + u.CmdName + "/" + u.CurrentVersion + "/" + u.Info.Version + "/" + plat
-
-	// Attempt to perform an update from the URL
-	err, _ := up.FromUrl(patchURL)
-	if err == nil {
+	if u.ConfirmUpdate != nil && !u.ConfirmUpdate(u.CurrentVersion, u.Info.Version, u.Info.Size) {
 		return nil
 	}
 
-	// Construct the full binary URL
-	binURL := u.BinURL + u.CmdName + "/" + u.Info.Version + "/" + plat + ".gz"
+	backupPath, err := u.backupCurrentBinary()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	src := u.source()
+
+	up := update.New().ApplyPatch(update.PATCHTYPE_BSDIFF).VerifyChecksum(u.Info.Sha256)
+
+	// Attempt to perform an update via a patch
+	if patch, perr := src.FetchPatch(ctx, u.CurrentVersion, u.Info.Version); perr == nil {
+		// u.Info.Size is the full binary's size, not the (much smaller)
+		// patch's, so report an unknown total here rather than one the
+		// patch stream will never reach.
+		err, _ = up.FromStream(u.withProgress(patch, 0))
+		patch.Close()
+		if err == nil {
+			return u.commitUpdate(backupPath)
+		}
+	}
 
-	// Update by patching failed - let's try updating the full binary
+	// Patching wasn't available or failed - fetch and apply the full binary
+	bin, err := src.FetchBinary(ctx, u.Info.Version)
+	if err != nil {
+		return err
+	}
 	up.ApplyPatch(update.PATCHTYPE_NONE)
-	err, errRecover := up.FromUrl(binURL)
+	err, errRecover := up.FromStream(u.withProgress(bin, u.Info.Size))
+	bin.Close()
 	if errRecover != nil {
 		return fmt.Errorf("update and recovery errors: %q %q", err, errRecover)
 	}
@@ -147,7 +222,23 @@ func (u *Updater) Update() error {
 		return err
 	}
 
-	return nil
+	return u.commitUpdate(backupPath)
+}
+
+// source returns the Source to fetch manifests and binaries from, defaulting
+// to a HerokuSource built from ApiURL/BinURL/DiffURL/Requester when Source
+// isn't set.
+func (u *Updater) source() Source {
+	if u.Source != nil {
+		return u.Source
+	}
+	return &HerokuSource{
+		ApiURL:    u.ApiURL,
+		BinURL:    u.BinURL,
+		DiffURL:   u.DiffURL,
+		CmdName:   u.CmdName,
+		Requester: u.Requester,
+	}
 }
 
 // UpdateAvailable returns true if an update is available, and false otherwise.
@@ -160,39 +251,35 @@ func (u *Updater) UpdateAvailable() (bool, error) {
 	if u.Info.Version == u.CurrentVersion {
 		return false, nil
 	}
-
-	return true, nil
-}
-
-func (u *Updater) fetch(url string) (io.ReadCloser, error) {
-	if u.Requester == nil {
-		u.Requester = &HTTPRequester{}
+	if u.Info.MinVersion != "" && semverLess(u.CurrentVersion, u.Info.MinVersion) {
+		return false, nil
 	}
 
-	readCloser, err := u.Requester.Fetch(url)
+	inCohort, err := u.inRolloutCohort(u.Info.Version)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-
-	if readCloser == nil {
-		return nil, fmt.Errorf("Fetch was expected to return non-nil ReadCloser")
+	if !inCohort {
+		return false, nil
 	}
 
-	return readCloser, nil
+	return true, nil
 }
 
 func (u *Updater) fetchInfo() error {
-	r, err := u.fetch(u.ApiURL + u.CmdName + "/" + plat + ".json")
-	if err != nil {
-		return err
-	}
-	defer r.Close()
-	err = json.NewDecoder(r).Decode(&u.Info)
+	info, err := u.source().LatestVersion(context.Background(), u.Channel)
 	if err != nil {
 		return err
 	}
+	u.Info = info
 	if len(u.Info.Sha256) != sha256.Size {
 		return ErrInvalidHashLength
 	}
+	if u.PublicKey != nil {
+		payload := signedPayload(u.Info.Version, u.Info.Sha256)
+		if err := verifySignature(u.PublicKey, payload, u.Info.Signature); err != nil {
+			return err
+		}
+	}
 	return nil
 }
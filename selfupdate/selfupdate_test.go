@@ -0,0 +1,64 @@
+package selfupdate
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// fakeSource reports a fixed Info and is never expected to serve a binary
+// or patch in these tests: Update() should return before fetching either
+// when ConfirmUpdate declines.
+type fakeSource struct {
+	info Info
+}
+
+func (s *fakeSource) LatestVersion(ctx context.Context, channel string) (Info, error) {
+	return s.info, nil
+}
+
+func (s *fakeSource) FetchBinary(ctx context.Context, version string) (io.ReadCloser, error) {
+	return nil, errUnexpectedFetch
+}
+
+func (s *fakeSource) FetchPatch(ctx context.Context, from, to string) (io.ReadCloser, error) {
+	return nil, errUnexpectedFetch
+}
+
+var errUnexpectedFetch = errUnexpected{}
+
+type errUnexpected struct{}
+
+func (errUnexpected) Error() string { return "fakeSource: unexpected fetch" }
+
+func TestUpdateConfirmUpdateDeclines(t *testing.T) {
+	withTestExecutable(t)
+	sum := sha256.Sum256([]byte("binary-contents"))
+
+	var gotCurrent, gotNext string
+	var gotSize int64
+	u := &Updater{
+		CmdName:        "testapp",
+		Dir:            "update/",
+		CurrentVersion: "1.0.0",
+		Source:         &fakeSource{info: Info{Version: "1.1.0", Sha256: sum[:], Size: 42}},
+		ConfirmUpdate: func(current, next string, size int64) bool {
+			gotCurrent, gotNext, gotSize = current, next, size
+			return false
+		},
+	}
+
+	if err := u.Update(); err != nil {
+		t.Fatalf("Update() = %v, want nil", err)
+	}
+	if gotCurrent != "1.0.0" || gotNext != "1.1.0" || gotSize != 42 {
+		t.Fatalf("ConfirmUpdate called with (%q, %q, %d), want (%q, %q, %d)",
+			gotCurrent, gotNext, gotSize, "1.0.0", "1.1.0", int64(42))
+	}
+
+	if _, err := ioutil.ReadDir(u.getExecRelativeDir(u.Dir)); err == nil {
+		t.Fatal("Update() backed up the current binary despite ConfirmUpdate declining")
+	}
+}
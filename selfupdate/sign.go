@@ -0,0 +1,44 @@
+package selfupdate
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrSignatureMismatch is returned when PublicKey is set but the manifest's
+// Signature does not verify against it.
+var ErrSignatureMismatch = errors.New("selfupdate: signature verification failed")
+
+// ErrUnsupportedPublicKey is returned when PublicKey is set to a key type
+// this package does not know how to verify against.
+var ErrUnsupportedPublicKey = errors.New("selfupdate: unsupported public key type")
+
+// signedPayload returns the bytes a manifest Signature is expected to cover:
+// the target version and its SHA256, concatenated.
+func signedPayload(version string, sha256 []byte) []byte {
+	return append([]byte(version), sha256...)
+}
+
+// verifySignature checks sig against payload using pub. *ecdsa.PublicKey
+// verifies an ASN.1 signature over sha256(payload); ed25519.PublicKey
+// verifies directly over payload, per the ed25519 package's contract.
+func verifySignature(pub crypto.PublicKey, payload, sig []byte) error {
+	switch key := pub.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, payload, sig) {
+			return ErrSignatureMismatch
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(key, digest[:], sig) {
+			return ErrSignatureMismatch
+		}
+		return nil
+	default:
+		return ErrUnsupportedPublicKey
+	}
+}
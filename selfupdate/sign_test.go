@@ -0,0 +1,66 @@
+package selfupdate
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestSignedPayload(t *testing.T) {
+	got := signedPayload("1.2.3", []byte{0x01, 0x02})
+	want := append([]byte("1.2.3"), 0x01, 0x02)
+	if string(got) != string(want) {
+		t.Fatalf("signedPayload = %x, want %x", got, want)
+	}
+}
+
+func TestVerifySignatureEd25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := signedPayload("1.2.3", []byte{0x01, 0x02, 0x03})
+	sig := ed25519.Sign(priv, payload)
+
+	if err := verifySignature(pub, payload, sig); err != nil {
+		t.Fatalf("verifySignature() = %v, want nil", err)
+	}
+
+	tampered := append([]byte{}, payload...)
+	tampered[0] ^= 0xff
+	if err := verifySignature(pub, tampered, sig); err != ErrSignatureMismatch {
+		t.Fatalf("verifySignature() on tampered payload = %v, want ErrSignatureMismatch", err)
+	}
+}
+
+func TestVerifySignatureECDSA(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := signedPayload("1.2.3", []byte{0x01, 0x02, 0x03})
+	digest := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifySignature(&priv.PublicKey, payload, sig); err != nil {
+		t.Fatalf("verifySignature() = %v, want nil", err)
+	}
+
+	tampered := append([]byte{}, payload...)
+	tampered[0] ^= 0xff
+	if err := verifySignature(&priv.PublicKey, tampered, sig); err != ErrSignatureMismatch {
+		t.Fatalf("verifySignature() on tampered payload = %v, want ErrSignatureMismatch", err)
+	}
+}
+
+func TestVerifySignatureUnsupportedKey(t *testing.T) {
+	if err := verifySignature("not-a-key", []byte("payload"), []byte("sig")); err != ErrUnsupportedPublicKey {
+		t.Fatalf("verifySignature() with unsupported key = %v, want ErrUnsupportedPublicKey", err)
+	}
+}
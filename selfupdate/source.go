@@ -0,0 +1,32 @@
+package selfupdate
+
+import (
+	"context"
+	"io"
+)
+
+// Info describes an available update as reported by a Source.
+type Info struct {
+	Version    string
+	Sha256     []byte
+	Signature  []byte  `json:",omitempty"` // optional, base64-decoded; see Updater.PublicKey
+	Size       int64   `json:",omitempty"` // optional download size in bytes, for Updater.ConfirmUpdate
+	Rollout    float64 `json:",omitempty"` // optional staged-rollout fraction in [0,1]; see Updater.InstallID
+	MinVersion string  `json:",omitempty"` // optional; installs older than this are skipped
+}
+
+// Source abstracts where update manifests and binaries come from, so
+// Updater isn't tied to the original Heroku-style flat S3 layout. See
+// HerokuSource and GitHubReleasesSource for the built-in implementations.
+type Source interface {
+	// LatestVersion returns the newest Info available on channel (e.g.
+	// "stable", "beta", "nightly"). Sources that don't support channels
+	// should ignore the argument.
+	LatestVersion(ctx context.Context, channel string) (Info, error)
+	// FetchBinary returns the full, ready-to-apply binary for version.
+	FetchBinary(ctx context.Context, version string) (io.ReadCloser, error)
+	// FetchPatch returns a bsdiff patch that transforms from into to. A
+	// Source that can't produce patches should return an error so Updater
+	// falls back to FetchBinary.
+	FetchPatch(ctx context.Context, from, to string) (io.ReadCloser, error)
+}